@@ -0,0 +1,124 @@
+package logger
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// stripHostPort removes a trailing ":port" and any surrounding "[]" brackets
+// from an address, so "[::1]:1234" and "1.2.3.4:5678" both come back as bare
+// IPs ("::1" and "1.2.3.4" respectively).
+func stripHostPort(addr string) string {
+	addr = strings.TrimSpace(addr)
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return strings.Trim(addr, "[]")
+}
+
+// isTrustedProxy reports whether addr falls inside one of the trusted networks.
+func isTrustedProxy(addr string, trusted []net.IPNet) bool {
+	if len(trusted) == 0 {
+		return false
+	}
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, n := range trusted {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitForwardedList splits a comma-separated header value (as used by
+// X-Forwarded-For) into its individual, trimmed hops.
+func splitForwardedList(val string) []string {
+	parts := strings.Split(val, ",")
+	hops := make([]string, 0, len(parts))
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			hops = append(hops, p)
+		}
+	}
+	return hops
+}
+
+// parseForwardedHeader extracts, in order, every `for=` token from an RFC
+// 7239 `Forwarded` header value, e.g. `for=192.0.2.60;proto=http, for="[::1]:80"`.
+func parseForwardedHeader(val string) []string {
+	var hops []string
+	for _, element := range strings.Split(val, ",") {
+		for _, pair := range strings.Split(element, ";") {
+			pair = strings.TrimSpace(pair)
+			kv := strings.SplitN(pair, "=", 2)
+			if len(kv) != 2 || !strings.EqualFold(strings.TrimSpace(kv[0]), "for") {
+				continue
+			}
+			v := strings.TrimSpace(kv[1])
+			v = strings.Trim(v, `"`)
+			if v != "" {
+				hops = append(hops, v)
+			}
+		}
+	}
+	return hops
+}
+
+// resolveClientAddr picks the real client address out of a proxy hop chain
+// (ordered client-first, as it appears in X-Forwarded-For/Forwarded) plus the
+// directly connected remoteAddr.
+//
+// With no TrustedProxies configured, it simply returns the left-most
+// non-empty hop (the historical, RFC-naive behavior). With TrustedProxies
+// configured, it walks the chain right-to-left starting at remoteAddr and
+// returns the first hop that is not a trusted proxy, so spoofed left-most
+// entries from outside the trusted network can't be used to fake a client IP.
+func resolveClientAddr(remoteAddr string, hops []string, trusted []net.IPNet) string {
+	if len(hops) == 0 {
+		return remoteAddr
+	}
+
+	if len(trusted) == 0 {
+		return stripHostPort(hops[0])
+	}
+
+	if !isTrustedProxy(stripHostPort(remoteAddr), trusted) {
+		return stripHostPort(remoteAddr)
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := stripHostPort(hops[i])
+		if i == 0 || !isTrustedProxy(hop, trusted) {
+			return hop
+		}
+	}
+	return stripHostPort(hops[0])
+}
+
+// determineRemoteAddr figures out the client address for r, honoring
+// Options.ForwardedHeader, Options.RemoteAddressHeaders and
+// Options.TrustedProxies.
+func determineRemoteAddr(r *http.Request, opt Options) string {
+	if opt.ForwardedHeader {
+		if val := r.Header.Get("Forwarded"); len(val) > 0 {
+			if hops := parseForwardedHeader(val); len(hops) > 0 {
+				return resolveClientAddr(r.RemoteAddr, hops, opt.TrustedProxies)
+			}
+		}
+	}
+
+	for _, headerKey := range opt.RemoteAddressHeaders {
+		val := r.Header.Get(headerKey)
+		if len(val) == 0 {
+			continue
+		}
+		return resolveClientAddr(r.RemoteAddr, splitForwardedList(val), opt.TrustedProxies)
+	}
+
+	return r.RemoteAddr
+}
@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"bytes"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStripHostPort(t *testing.T) {
+	expect(t, stripHostPort("1.2.3.4:5678"), "1.2.3.4")
+	expect(t, stripHostPort("1.2.3.4"), "1.2.3.4")
+	expect(t, stripHostPort("[::1]:1234"), "::1")
+	expect(t, stripHostPort("[::1]"), "::1")
+}
+
+func TestResolveClientAddrNoTrustedProxies(t *testing.T) {
+	addr := resolveClientAddr("9.9.9.9:1111", []string{"1.2.3.4", "5.6.7.8"}, nil)
+	expect(t, addr, "1.2.3.4")
+}
+
+func TestResolveClientAddrTrustedProxies(t *testing.T) {
+	_, cidr, _ := net.ParseCIDR("9.9.9.0/24")
+	trusted := []net.IPNet{*cidr}
+
+	// RemoteAddr (9.9.9.9) is trusted, so we look at the header chain; the
+	// right-most hop (5.6.7.8) is also trusted, so keep walking left to the
+	// real, untrusted client.
+	addr := resolveClientAddr("9.9.9.9:1111", []string{"1.2.3.4", "5.6.7.8"}, append(trusted, mustCIDR("5.6.7.8/32")))
+	expect(t, addr, "1.2.3.4")
+}
+
+func TestResolveClientAddrUntrustedRemote(t *testing.T) {
+	_, cidr, _ := net.ParseCIDR("9.9.9.0/24")
+	trusted := []net.IPNet{*cidr}
+
+	// RemoteAddr itself isn't trusted, so the header chain can't be trusted either.
+	addr := resolveClientAddr("8.8.8.8:1111", []string{"1.2.3.4"}, trusted)
+	expect(t, addr, "8.8.8.8")
+}
+
+func mustCIDR(s string) net.IPNet {
+	_, n, _ := net.ParseCIDR(s)
+	return *n
+}
+
+func TestParseForwardedHeader(t *testing.T) {
+	hops := parseForwardedHeader(`for=192.0.2.60;proto=http;by=203.0.113.43, for="[2001:db8::1]:4711"`)
+	expect(t, len(hops), 2)
+	expect(t, hops[0], "192.0.2.60")
+	expect(t, hops[1], "[2001:db8::1]:4711")
+}
+
+func TestDetermineRemoteAddrForwardedHeader(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/foo", nil)
+	req.RemoteAddr = "8.8.4.4"
+	req.Header.Set("Forwarded", "for=12.34.56.78;proto=https")
+
+	addr := determineRemoteAddr(req, Options{ForwardedHeader: true})
+	expect(t, addr, "12.34.56.78")
+}
+
+func TestLoggerUsesForwardedHeader(t *testing.T) {
+	buf := bytes.NewBufferString("")
+
+	l := New(Options{
+		Out:             buf,
+		ForwardedHeader: true,
+	})
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/foo", nil)
+	req.RemoteAddr = "8.8.4.4"
+	req.Header.Set("Forwarded", `for="[::1]:1234"`)
+	l.Handler(myHandler).ServeHTTP(res, req)
+
+	expectContainsTrue(t, buf.String(), "(::1)")
+	expectContainsFalse(t, buf.String(), req.RemoteAddr)
+}
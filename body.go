@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// defaultBodyContentTypes is used when Options.BodyContentTypes is left unset.
+var defaultBodyContentTypes = []string{"application/json", "text/*", "application/x-www-form-urlencoded"}
+
+// Redactor strips sensitive data (passwords, tokens, ...) out of a captured
+// request or response body before it is logged. header is the request or
+// response header the body came from.
+type Redactor func(body []byte, header http.Header) []byte
+
+// contentTypeAllowed reports whether ct (a Content-Type header value, which
+// may include parameters such as "; charset=utf-8") matches one of allowed.
+// A "type/*" entry matches any subtype of type.
+func contentTypeAllowed(ct string, allowed []string) bool {
+	if ct == "" {
+		return false
+	}
+	if i := strings.IndexByte(ct, ';'); i != -1 {
+		ct = ct[:i]
+	}
+	ct = strings.TrimSpace(strings.ToLower(ct))
+
+	for _, a := range allowed {
+		a = strings.ToLower(a)
+		if strings.HasSuffix(a, "/*") {
+			if strings.HasPrefix(ct, a[:len(a)-1]) {
+				return true
+			}
+			continue
+		}
+		if ct == a {
+			return true
+		}
+	}
+	return false
+}
+
+// cappedWriter writes only the first max bytes it ever sees into buf, while
+// still reporting a full, successful write to its caller. This lets it sit
+// behind an io.TeeReader without truncating (or erroring on) the underlying
+// read, which matters for chunked request bodies and streamed responses.
+type cappedWriter struct {
+	buf *bytes.Buffer
+	max int
+}
+
+func (c *cappedWriter) Write(p []byte) (int, error) {
+	if room := c.max - c.buf.Len(); room > 0 {
+		if room > len(p) {
+			room = len(p)
+		}
+		c.buf.Write(p[:room])
+	}
+	return len(p), nil
+}
+
+// teeReadCloser pairs a Reader (typically an io.TeeReader) with the original
+// body's Closer, so replacing r.Body doesn't lose the ability to close it.
+type teeReadCloser struct {
+	io.Reader
+	closer io.Closer
+}
+
+func (t *teeReadCloser) Close() error {
+	return t.closer.Close()
+}
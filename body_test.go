@@ -0,0 +1,135 @@
+package logger
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestContentTypeAllowed(t *testing.T) {
+	expect(t, contentTypeAllowed("application/json", defaultBodyContentTypes), true)
+	expect(t, contentTypeAllowed("application/json; charset=utf-8", defaultBodyContentTypes), true)
+	expect(t, contentTypeAllowed("text/plain", defaultBodyContentTypes), true)
+	expect(t, contentTypeAllowed("application/octet-stream", defaultBodyContentTypes), false)
+	expect(t, contentTypeAllowed("", defaultBodyContentTypes), false)
+}
+
+func TestCaptureRequestBody(t *testing.T) {
+	buf := bytes.NewBufferString("")
+
+	echoBody := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		w.Write(b)
+	})
+
+	l := New(Options{
+		Out:                buf,
+		Formatter:          JSONFormatter{},
+		CaptureRequestBody: true,
+		MaxBodyBytes:       1024,
+	})
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/foo", bytes.NewBufferString(`{"user":"bob"}`))
+	req.Header.Set("Content-Type", "application/json")
+	l.Handler(echoBody).ServeHTTP(res, req)
+
+	expect(t, res.Body.String(), `{"user":"bob"}`)
+	expectContainsTrue(t, buf.String(), `"request_body":"{\"user\":\"bob\"}"`)
+}
+
+func TestCaptureRequestBodyTruncated(t *testing.T) {
+	buf := bytes.NewBufferString("")
+
+	readBody := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		w.Write([]byte("bar"))
+	})
+
+	l := New(Options{
+		Out:                buf,
+		Formatter:          JSONFormatter{},
+		CaptureRequestBody: true,
+		MaxBodyBytes:       3,
+	})
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/foo", bytes.NewBufferString(`{"user":"bob"}`))
+	req.Header.Set("Content-Type", "application/json")
+	l.Handler(readBody).ServeHTTP(res, req)
+
+	expectContainsTrue(t, buf.String(), `"request_body":"{\"u"`)
+}
+
+func TestCaptureResponseBody(t *testing.T) {
+	buf := bytes.NewBufferString("")
+
+	l := New(Options{
+		Out:                 buf,
+		Formatter:           JSONFormatter{},
+		CaptureResponseBody: true,
+		MaxBodyBytes:        1024,
+	})
+
+	jsonHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true}`))
+	})
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/foo", nil)
+	l.Handler(jsonHandler).ServeHTTP(res, req)
+
+	expectContainsTrue(t, buf.String(), `"response_body":"{\"ok\":true}"`)
+}
+
+func TestCaptureResponseBodySkipsDisallowedContentType(t *testing.T) {
+	buf := bytes.NewBufferString("")
+
+	l := New(Options{
+		Out:                 buf,
+		Formatter:           JSONFormatter{},
+		CaptureResponseBody: true,
+		MaxBodyBytes:        1024,
+	})
+
+	binHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write([]byte{0x01, 0x02})
+	})
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/foo", nil)
+	l.Handler(binHandler).ServeHTTP(res, req)
+
+	expectContainsFalse(t, buf.String(), `"response_body"`)
+}
+
+func TestRedactor(t *testing.T) {
+	buf := bytes.NewBufferString("")
+
+	readBody := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		w.Write([]byte("bar"))
+	})
+
+	l := New(Options{
+		Out:                buf,
+		Formatter:          JSONFormatter{},
+		CaptureRequestBody: true,
+		MaxBodyBytes:       1024,
+		Redactor: func(b []byte, h http.Header) []byte {
+			return []byte("[redacted]")
+		},
+	})
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/foo", bytes.NewBufferString(`{"password":"secret"}`))
+	req.Header.Set("Content-Type", "application/json")
+	l.Handler(readBody).ServeHTTP(res, req)
+
+	expectContainsTrue(t, buf.String(), `"request_body":"[redacted]"`)
+	expectContainsFalse(t, buf.String(), "secret")
+}
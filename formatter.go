@@ -0,0 +1,147 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Entry represents the information gathered about a single request/response
+// cycle. It is handed to a Formatter so the caller has full control over how
+// (and whether) each field is rendered.
+type Entry struct {
+	Time       time.Time
+	RemoteAddr string
+	Method     string
+	URI        string
+	Proto      string
+	Status     int
+	Size       int
+	Duration   time.Duration
+	UserAgent  string
+	Referer    string
+	Host       string
+
+	// RequestBody and ResponseBody hold up to Options.MaxBodyBytes of the
+	// request/response bodies when the corresponding Options.Capture*Body
+	// flag is set and the content type is allowed. They are nil otherwise.
+	RequestBody  []byte
+	ResponseBody []byte
+
+	// TraceID and SpanID identify this request for correlation with a
+	// tracing backend. See Options.TraceIDHeaders.
+	TraceID string
+	SpanID  string
+}
+
+// Formatter turns a completed Entry into a line of output. Implementations
+// are responsible for their own trailing newline.
+type Formatter interface {
+	Format(e Entry) ([]byte, error)
+}
+
+// TextFormatter renders an Entry using the plain-text layout Logger has
+// always produced: (addr) "METHOD URI PROTO" status size duration, followed
+// by trace=... span=... and, when captured, reqBody=... and resBody=....
+type TextFormatter struct{}
+
+// Format implements Formatter.
+func (TextFormatter) Format(e Entry) ([]byte, error) {
+	line := fmt.Sprintf("(%s) \"%s %s %s\" %d %d %s trace=%s span=%s", e.RemoteAddr, e.Method, e.URI, e.Proto, e.Status, e.Size, e.Duration, e.TraceID, e.SpanID)
+	if len(e.RequestBody) > 0 {
+		line += fmt.Sprintf(" reqBody=%q", e.RequestBody)
+	}
+	if len(e.ResponseBody) > 0 {
+		line += fmt.Sprintf(" resBody=%q", e.ResponseBody)
+	}
+	return []byte(line + "\n"), nil
+}
+
+// JSONFormatter renders an Entry as a single-line JSON object, suitable for
+// shipping to log aggregators such as ELK or Loki without any post-processing.
+type JSONFormatter struct{}
+
+// Format implements Formatter.
+func (JSONFormatter) Format(e Entry) ([]byte, error) {
+	rec := struct {
+		Time       string  `json:"time"`
+		RemoteAddr string  `json:"remote_addr"`
+		Method     string  `json:"method"`
+		URI        string  `json:"uri"`
+		Proto      string  `json:"proto"`
+		Status     int     `json:"status"`
+		Size       int     `json:"size"`
+		DurationMS float64 `json:"duration_ms"`
+		UserAgent  string  `json:"user_agent"`
+		Referer    string  `json:"referer"`
+		Host       string  `json:"host"`
+		ReqBody    string  `json:"request_body,omitempty"`
+		ResBody    string  `json:"response_body,omitempty"`
+		TraceID    string  `json:"trace_id,omitempty"`
+		SpanID     string  `json:"span_id,omitempty"`
+	}{
+		Time:       e.Time.Format(time.RFC3339),
+		RemoteAddr: e.RemoteAddr,
+		Method:     e.Method,
+		URI:        e.URI,
+		Proto:      e.Proto,
+		Status:     e.Status,
+		Size:       e.Size,
+		DurationMS: float64(e.Duration) / float64(time.Millisecond),
+		UserAgent:  e.UserAgent,
+		Referer:    e.Referer,
+		Host:       e.Host,
+		ReqBody:    string(e.RequestBody),
+		ResBody:    string(e.ResponseBody),
+		TraceID:    e.TraceID,
+		SpanID:     e.SpanID,
+	}
+
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// escapeCLFQuoted escapes a value destined for a quoted CLF field, matching
+// gorilla/handlers' appendQuoted: backslashes and double quotes are
+// backslash-escaped, and other control characters are replaced with a space,
+// so an attacker-controlled header (eg. User-Agent, Referer) can't close the
+// quoted field early and forge additional log entries.
+func escapeCLFQuoted(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '"':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r == 0x7f:
+			b.WriteByte(' ')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// CombinedLogFormatter renders an Entry using the Apache Combined Log Format,
+// matching the output of gorilla/handlers.CombinedLoggingHandler.
+type CombinedLogFormatter struct{}
+
+// Format implements Formatter.
+func (CombinedLogFormatter) Format(e Entry) ([]byte, error) {
+	ref := e.Referer
+	if ref == "" {
+		ref = "-"
+	}
+	ua := e.UserAgent
+	if ua == "" {
+		ua = "-"
+	}
+
+	line := fmt.Sprintf("%s - - [%s] \"%s %s %s\" %d %d \"%s\" \"%s\"\n",
+		e.RemoteAddr, e.Time.Format("02/Jan/2006:15:04:05 -0700"), escapeCLFQuoted(e.Method), escapeCLFQuoted(e.URI), escapeCLFQuoted(e.Proto), e.Status, e.Size, escapeCLFQuoted(ref), escapeCLFQuoted(ua))
+	return []byte(line), nil
+}
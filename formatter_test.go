@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTextFormatter(t *testing.T) {
+	buf := bytes.NewBufferString("")
+
+	l := New(Options{
+		Out:       buf,
+		Formatter: TextFormatter{},
+	})
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/foo", nil)
+	req.RequestURI = "/foo"
+	l.Handler(myHandler).ServeHTTP(res, req)
+
+	expectContainsTrue(t, buf.String(), "\"GET /foo HTTP/1.1\" 200 3")
+}
+
+func TestJSONFormatter(t *testing.T) {
+	buf := bytes.NewBufferString("")
+
+	l := New(Options{
+		Out:       buf,
+		Formatter: JSONFormatter{},
+	})
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/foo", nil)
+	req.Header.Set("User-Agent", "test-agent")
+	l.Handler(myHandler).ServeHTTP(res, req)
+
+	expectContainsTrue(t, buf.String(), "\"method\":\"GET\"")
+	expectContainsTrue(t, buf.String(), "\"status\":200")
+	expectContainsTrue(t, buf.String(), "\"user_agent\":\"test-agent\"")
+}
+
+func TestCombinedLogFormatter(t *testing.T) {
+	buf := bytes.NewBufferString("")
+
+	l := New(Options{
+		Out:       buf,
+		Formatter: CombinedLogFormatter{},
+	})
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/foo", nil)
+	req.RequestURI = "/foo"
+	req.RemoteAddr = "8.8.4.4"
+	l.Handler(myHandler).ServeHTTP(res, req)
+
+	expectContainsTrue(t, buf.String(), "8.8.4.4 - - [")
+	expectContainsTrue(t, buf.String(), "\"GET /foo HTTP/1.1\" 200 3 \"-\" \"-\"")
+}
+
+func TestCombinedLogFormatterEscapesInjection(t *testing.T) {
+	buf := bytes.NewBufferString("")
+
+	l := New(Options{
+		Out:       buf,
+		Formatter: CombinedLogFormatter{},
+	})
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/foo", nil)
+	req.RequestURI = "/foo"
+	req.RemoteAddr = "8.8.4.4"
+	req.Header.Set("User-Agent", `evil" 999 1 "injected`)
+	l.Handler(myHandler).ServeHTTP(res, req)
+
+	if strings.Contains(buf.String(), `"evil" 999 1 "injected"`) {
+		t.Fatalf("unescaped User-Agent forged a second log entry: %s", buf.String())
+	}
+	expectContainsTrue(t, buf.String(), `\"injected`)
+}
+
+func TestJSONFormatterNoError(t *testing.T) {
+	e := Entry{Time: time.Now()}
+	f := JSONFormatter{}
+	if _, err := f.Format(e); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package logger
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"io"
 	"log"
@@ -9,6 +10,8 @@ import (
 	"net/http"
 	"os"
 	"time"
+
+	"github.com/unrolled/logger/sink"
 )
 
 // Options is a struct for specifying configuration parameters for the Logger middleware.
@@ -17,14 +20,38 @@ type Options struct {
 	Prefix string
 	// DisableAutoBrackets if set to true, will remove the prefix and square brackets. Default is false.
 	DisableAutoBrackets bool
-	// RemoteAddressHeaders is a list of header keys that Logger will look at to determine the proper remote address. Useful when using a proxy like Nginx: `[]string{"X-Forwarded-Proto"}`. Default is an empty slice, and thus will use `reqeust.RemoteAddr`.
+	// RemoteAddressHeaders is a list of header keys that Logger will look at to determine the proper remote address. Useful when using a proxy like Nginx: `[]string{"X-Forwarded-Proto"}`. Default is an empty slice, and thus will use `reqeust.RemoteAddr`. Values are treated as a comma-separated hop chain (as `X-Forwarded-For` is), with brackets/ports stripped from each hop.
 	RemoteAddressHeaders []string
+	// ForwardedHeader, when true, parses the standard RFC 7239 `Forwarded` header (eg. `for=1.2.3.4;proto=https`) in preference to RemoteAddressHeaders.
+	ForwardedHeader bool
+	// TrustedProxies is a list of networks that are allowed to set remote-address headers. When set, the hop chain is walked right-to-left starting at `request.RemoteAddr`, skipping trusted proxies, so an untrusted client can't spoof the address by injecting a fake left-most entry. Default is an empty slice, which preserves the simpler left-most-entry behavior.
+	TrustedProxies []net.IPNet
 	// Out is the destination to which the logged data will be written too. Default is `os.Stdout`.
 	Out io.Writer
 	// OutputFlags defines the logging properties. See http://golang.org/pkg/log/#pkg-constants. To disable all flags, set this to `-1`. Defaults to log.LstdFlags (2009/01/23 01:23:23).
 	OutputFlags int
 	// IgnoredRequestURIs is a list of path values we do not want logged out. Exact match only!
 	IgnoredRequestURIs []string
+	// Formatter, when set, is used to render each request as a log line instead of the default plain-text layout. Built-in options are `TextFormatter`, `JSONFormatter`, and `CombinedLogFormatter`. Default is nil, which keeps the historical `Printf`-based output (and thus `Prefix`/`OutputFlags`) untouched, with a `trace=... span=...` suffix appended only when `TraceIDHeaders` or `RequestIDGenerator` is explicitly configured.
+	Formatter Formatter
+	// Sampler, when set, is consulted for every completed request not matched by IgnoredRequestURIs and controls whether it gets logged. Built-in options are `FixedRateSampler`, `TokenBucketSampler`, and `AlwaysLogErrorsSampler`. Default is nil, which logs every request.
+	Sampler Sampler
+	// CaptureRequestBody, when true, buffers up to MaxBodyBytes of the request body (if its Content-Type is allowed by BodyContentTypes) for inclusion in the log entry. Captured bodies are only logged when Formatter is set; the default output (Formatter nil) leaves them out.
+	CaptureRequestBody bool
+	// CaptureResponseBody, when true, buffers up to MaxBodyBytes of the response body (if its Content-Type is allowed by BodyContentTypes) for inclusion in the log entry; as with CaptureRequestBody, it is only logged when Formatter is set. Capture is skipped once the handler calls Hijack (eg. WebSockets).
+	CaptureResponseBody bool
+	// MaxBodyBytes caps how much of a request/response body is buffered when capture is enabled. A value of 0 disables capture entirely.
+	MaxBodyBytes int
+	// BodyContentTypes is the Content-Type allowlist consulted by CaptureRequestBody/CaptureResponseBody. A "type/*" entry matches any subtype. Default is `{"application/json", "text/*", "application/x-www-form-urlencoded"}`.
+	BodyContentTypes []string
+	// Redactor, when set, is applied to a captured request/response body before it is logged, so callers can strip passwords/tokens/etc.
+	Redactor Redactor
+	// TraceIDHeaders is the ordered list of headers Handler checks for an inbound trace ID. `traceparent` is parsed per W3C; the rest are used verbatim. Default is `{"traceparent", "X-Request-ID", "X-Amzn-Trace-Id"}`.
+	TraceIDHeaders []string
+	// RequestIDGenerator is used to mint a trace ID when none of TraceIDHeaders is present on the request. Default generates a random 16-byte hex ID.
+	RequestIDGenerator func() string
+	// Sinks is an additional set of severity-filtered destinations that receive a copy of every rendered log line (eg. `sink.RotatingFile`, or `os.Stderr` with `MinLevel: sink.LevelError`), alongside whatever Out/Formatter already produce. A response status >= 500 is LevelError, >= 400 is LevelWarn, otherwise LevelInfo. Default is an empty slice.
+	Sinks []sink.Sink
 }
 
 // Logger is a HTTP middleware handler that logs a request. Outputted information includes status, method, URL, remote address, size, and the time it took to process the request.
@@ -76,7 +103,29 @@ func (l *Logger) Handler(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
-		crw := newCustomResponseWriter(w)
+		bodyContentTypes := l.opt.BodyContentTypes
+		if len(bodyContentTypes) == 0 {
+			bodyContentTypes = defaultBodyContentTypes
+		}
+
+		var reqBodyBuf *bytes.Buffer
+		if l.opt.CaptureRequestBody && l.opt.MaxBodyBytes > 0 && r.Body != nil && contentTypeAllowed(r.Header.Get("Content-Type"), bodyContentTypes) {
+			reqBodyBuf = &bytes.Buffer{}
+			r.Body = &teeReadCloser{
+				Reader: io.TeeReader(r.Body, &cappedWriter{buf: reqBodyBuf, max: l.opt.MaxBodyBytes}),
+				closer: r.Body,
+			}
+		}
+
+		traceIDHeaders := l.opt.TraceIDHeaders
+		if len(traceIDHeaders) == 0 {
+			traceIDHeaders = defaultTraceIDHeaders
+		}
+		traceID, spanID := resolveTraceID(r, traceIDHeaders, l.opt.RequestIDGenerator)
+		r = r.WithContext(withRequestID(r.Context(), traceID))
+		w.Header().Set("X-Request-ID", traceID)
+
+		crw := newCustomResponseWriter(w, l.opt.CaptureResponseBody, l.opt.MaxBodyBytes, bodyContentTypes)
 		next.ServeHTTP(crw, r)
 
 		for _, ignoredURI := range l.opt.IgnoredRequestURIs {
@@ -85,22 +134,96 @@ func (l *Logger) Handler(next http.Handler) http.Handler {
 			}
 		}
 
-		addr := r.RemoteAddr
-		for _, headerKey := range l.opt.RemoteAddressHeaders {
-			if val := r.Header.Get(headerKey); len(val) > 0 {
-				addr = val
-				break
+		if l.opt.Sampler != nil && !l.opt.Sampler.Sample(r, crw.status) {
+			return
+		}
+
+		addr := determineRemoteAddr(r, l.opt)
+
+		if l.opt.Formatter == nil {
+			line := fmt.Sprintf("(%s) \"%s %s %s\" %d %d %s", addr, r.Method, r.RequestURI, r.Proto, crw.status, crw.size, time.Since(start))
+			if len(l.opt.TraceIDHeaders) > 0 || l.opt.RequestIDGenerator != nil {
+				line += fmt.Sprintf(" trace=%s span=%s", traceID, spanID)
+			}
+			l.Print(line)
+			l.fanOutToSinks(crw.status, []byte(line+"\n"))
+			return
+		}
+
+		var reqBody, resBody []byte
+		if reqBodyBuf != nil {
+			reqBody = reqBodyBuf.Bytes()
+			if l.opt.Redactor != nil {
+				reqBody = l.opt.Redactor(reqBody, r.Header)
+			}
+		}
+		if crw.bodyAllowed && crw.bodyBuf.Len() > 0 {
+			resBody = crw.bodyBuf.Bytes()
+			if l.opt.Redactor != nil {
+				resBody = l.opt.Redactor(resBody, crw.Header())
 			}
 		}
 
-		l.Printf("(%s) \"%s %s %s\" %d %d %s", addr, r.Method, r.RequestURI, r.Proto, crw.status, crw.size, time.Since(start))
+		line, err := l.opt.Formatter.Format(Entry{
+			Time:         start,
+			RemoteAddr:   addr,
+			Method:       r.Method,
+			URI:          r.RequestURI,
+			Proto:        r.Proto,
+			Status:       crw.status,
+			Size:         crw.size,
+			Duration:     time.Since(start),
+			UserAgent:    r.UserAgent(),
+			Referer:      r.Referer(),
+			Host:         r.Host,
+			RequestBody:  reqBody,
+			ResponseBody: resBody,
+			TraceID:      traceID,
+			SpanID:       spanID,
+		})
+		if err != nil {
+			l.Printf("logger: formatter error: %v", err)
+			return
+		}
+		l.Writer().Write(line)
+		l.fanOutToSinks(crw.status, line)
 	})
 }
 
+// fanOutToSinks writes line to every configured Options.Sinks entry whose
+// MinLevel is at or below the severity implied by status.
+func (l *Logger) fanOutToSinks(status int, line []byte) {
+	if len(l.opt.Sinks) == 0 {
+		return
+	}
+
+	level := sink.LevelInfo
+	switch {
+	case status >= http.StatusInternalServerError:
+		level = sink.LevelError
+	case status >= http.StatusBadRequest:
+		level = sink.LevelWarn
+	}
+
+	for _, s := range l.opt.Sinks {
+		if level >= s.MinLevel {
+			s.Writer.Write(line)
+		}
+	}
+}
+
 type customResponseWriter struct {
 	http.ResponseWriter
 	status int
 	size   int
+
+	captureBody  bool
+	contentTypes []string
+	maxBytes     int
+	bodyChecked  bool
+	bodyAllowed  bool
+	bodyBuf      bytes.Buffer
+	hijacked     bool
 }
 
 func (c *customResponseWriter) WriteHeader(status int) {
@@ -111,6 +234,17 @@ func (c *customResponseWriter) WriteHeader(status int) {
 func (c *customResponseWriter) Write(b []byte) (int, error) {
 	size, err := c.ResponseWriter.Write(b)
 	c.size += size
+
+	if c.captureBody && !c.hijacked {
+		if !c.bodyChecked {
+			c.bodyChecked = true
+			c.bodyAllowed = contentTypeAllowed(c.Header().Get("Content-Type"), c.contentTypes)
+		}
+		if c.bodyAllowed {
+			(&cappedWriter{buf: &c.bodyBuf, max: c.maxBytes}).Write(b[:size])
+		}
+	}
+
 	return size, err
 }
 
@@ -121,16 +255,21 @@ func (c *customResponseWriter) Flush() {
 }
 
 func (c *customResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
-	if hj, ok := c.ResponseWriter.(http.Hijacker); ok {
-		return hj.Hijack()
+	hj, ok := c.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("ResponseWriter does not implement the Hijacker interface")
 	}
-	return nil, nil, fmt.Errorf("ResponseWriter does not implement the Hijacker interface")
+	c.hijacked = true
+	return hj.Hijack()
 }
 
-func newCustomResponseWriter(w http.ResponseWriter) *customResponseWriter {
+func newCustomResponseWriter(w http.ResponseWriter, captureBody bool, maxBytes int, contentTypes []string) *customResponseWriter {
 	// When WriteHeader is not called, it's safe to assume the status will be 200.
 	return &customResponseWriter{
 		ResponseWriter: w,
 		status:         200,
+		captureBody:    captureBody,
+		maxBytes:       maxBytes,
+		contentTypes:   contentTypes,
 	}
 }
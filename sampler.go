@@ -0,0 +1,147 @@
+package logger
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Sampler decides whether a given request/response should be logged. It lets
+// Logger.Handler scale on busy services (health checks, static assets, ...)
+// without either drowning stdout or silently dropping error information.
+type Sampler interface {
+	Sample(r *http.Request, status int) bool
+}
+
+// FixedRateSampler logs one request out of every N, regardless of route.
+type FixedRateSampler struct {
+	N       int
+	counter uint64
+}
+
+// NewFixedRateSampler returns a FixedRateSampler that logs 1 request in n.
+// An n of 1 or less logs every request.
+func NewFixedRateSampler(n int) *FixedRateSampler {
+	return &FixedRateSampler{N: n}
+}
+
+// Sample implements Sampler.
+func (s *FixedRateSampler) Sample(r *http.Request, status int) bool {
+	if s.N <= 1 {
+		return true
+	}
+	c := atomic.AddUint64(&s.counter, 1)
+	return c%uint64(s.N) == 0
+}
+
+// defaultTokenBucketIdleTTL is how long a bucket can sit unused before it's
+// eligible for eviction, when IdleTTL is left at its zero value.
+const defaultTokenBucketIdleTTL = 10 * time.Minute
+
+// tokenBucketSweepInterval is how many Sample calls pass between idle-bucket
+// sweeps. Sweeping isn't done on every call since it's an O(len(buckets))
+// scan; amortizing it keeps Sample cheap under high QPS.
+const tokenBucketSweepInterval = 1024
+
+// TokenBucketSampler is a per-route token-bucket rate limiter: each distinct
+// "METHOD path" combination gets its own bucket, so a spike on one route
+// doesn't starve the logging budget of another.
+//
+// Route cardinality must stay bounded (eg. a fixed set of endpoints, not raw
+// paths with embedded IDs like "/users/123") or the bucket map grows
+// unbounded between sweeps. Buckets idle for longer than IdleTTL are evicted
+// periodically to cap memory on long-running processes.
+type TokenBucketSampler struct {
+	Capacity float64
+	Refill   float64 // tokens added per second
+
+	// IdleTTL is how long a route's bucket may sit unused before it's
+	// evicted. Default, when zero, is 10 minutes.
+	IdleTTL time.Duration
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	calls   uint64
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucketSampler returns a TokenBucketSampler allowing bursts up to
+// capacity, refilled at refillPerSecond tokens/sec, per route.
+func NewTokenBucketSampler(capacity, refillPerSecond float64) *TokenBucketSampler {
+	return &TokenBucketSampler{
+		Capacity: capacity,
+		Refill:   refillPerSecond,
+		buckets:  make(map[string]*tokenBucket),
+	}
+}
+
+// Sample implements Sampler.
+func (s *TokenBucketSampler) Sample(r *http.Request, status int) bool {
+	key := r.Method + " " + r.URL.Path
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: s.Capacity, last: now}
+		s.buckets[key] = b
+	}
+
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * s.Refill
+		if b.tokens > s.Capacity {
+			b.tokens = s.Capacity
+		}
+		b.last = now
+	}
+
+	s.calls++
+	if s.calls%tokenBucketSweepInterval == 0 {
+		s.evictIdleLocked(now)
+	}
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// evictIdleLocked removes buckets that haven't been touched within IdleTTL.
+// Callers must hold s.mu.
+func (s *TokenBucketSampler) evictIdleLocked(now time.Time) {
+	ttl := s.IdleTTL
+	if ttl <= 0 {
+		ttl = defaultTokenBucketIdleTTL
+	}
+	for key, b := range s.buckets {
+		if now.Sub(b.last) > ttl {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// AlwaysLogErrorsSampler wraps another Sampler so that 4xx/5xx responses are
+// always logged, while everything else defers to the wrapped Sampler (if
+// any, otherwise everything is logged).
+type AlwaysLogErrorsSampler struct {
+	Sampler Sampler
+}
+
+// Sample implements Sampler.
+func (a AlwaysLogErrorsSampler) Sample(r *http.Request, status int) bool {
+	if status >= http.StatusBadRequest {
+		return true
+	}
+	if a.Sampler == nil {
+		return true
+	}
+	return a.Sampler.Sample(r, status)
+}
@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFixedRateSampler(t *testing.T) {
+	s := NewFixedRateSampler(3)
+	req, _ := http.NewRequest("GET", "/foo", nil)
+
+	got := []bool{}
+	for i := 0; i < 6; i++ {
+		got = append(got, s.Sample(req, 200))
+	}
+
+	expect(t, got[0], false)
+	expect(t, got[1], false)
+	expect(t, got[2], true)
+	expect(t, got[5], true)
+}
+
+func TestTokenBucketSamplerBurstThenDeny(t *testing.T) {
+	s := NewTokenBucketSampler(2, 0)
+	req, _ := http.NewRequest("GET", "/foo", nil)
+
+	expect(t, s.Sample(req, 200), true)
+	expect(t, s.Sample(req, 200), true)
+	expect(t, s.Sample(req, 200), false)
+}
+
+func TestTokenBucketSamplerPerRoute(t *testing.T) {
+	s := NewTokenBucketSampler(1, 0)
+	reqA, _ := http.NewRequest("GET", "/a", nil)
+	reqB, _ := http.NewRequest("GET", "/b", nil)
+
+	expect(t, s.Sample(reqA, 200), true)
+	expect(t, s.Sample(reqA, 200), false)
+	// A different route gets its own bucket.
+	expect(t, s.Sample(reqB, 200), true)
+}
+
+func TestTokenBucketSamplerEvictsIdleBuckets(t *testing.T) {
+	s := NewTokenBucketSampler(1, 0)
+	s.IdleTTL = time.Minute
+	s.buckets["GET /stale"] = &tokenBucket{tokens: 1, last: time.Now().Add(-time.Hour)}
+	s.buckets["GET /fresh"] = &tokenBucket{tokens: 1, last: time.Now()}
+
+	s.evictIdleLocked(time.Now())
+
+	if _, ok := s.buckets["GET /stale"]; ok {
+		t.Fatalf("expected stale bucket to be evicted")
+	}
+	if _, ok := s.buckets["GET /fresh"]; !ok {
+		t.Fatalf("expected fresh bucket to survive eviction")
+	}
+}
+
+func TestAlwaysLogErrorsSampler(t *testing.T) {
+	s := AlwaysLogErrorsSampler{Sampler: NewFixedRateSampler(100)}
+	req, _ := http.NewRequest("GET", "/foo", nil)
+
+	expect(t, s.Sample(req, http.StatusInternalServerError), true)
+	expect(t, s.Sample(req, http.StatusOK), false)
+}
+
+func TestLoggerWithSampler(t *testing.T) {
+	buf := bytes.NewBufferString("")
+
+	l := New(Options{
+		Out:     buf,
+		Sampler: AlwaysLogErrorsSampler{Sampler: NewFixedRateSampler(1000)},
+	})
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/foo", nil)
+	l.Handler(myHandler).ServeHTTP(res, req)
+
+	expect(t, buf.String(), "")
+}
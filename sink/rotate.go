@@ -0,0 +1,198 @@
+package sink
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RotatingFile is an io.WriteCloser that writes to Path, rolling the file
+// over once it exceeds MaxSizeBytes (if non-zero) or has been open longer
+// than MaxAge (if non-zero). Rolled-over segments are renamed alongside
+// Path with a timestamp suffix, optionally gzip-compressed, and pruned down
+// to MaxBackups (if non-zero) most-recent segments.
+type RotatingFile struct {
+	Path         string
+	MaxSizeBytes int64
+	MaxAge       time.Duration
+	MaxBackups   int
+	Compress     bool
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+}
+
+// NewRotatingFile returns a RotatingFile for path. A maxSizeBytes or maxAge
+// of 0 disables that rollover trigger; a maxBackups of 0 keeps every segment.
+func NewRotatingFile(path string, maxSizeBytes int64, maxAge time.Duration, maxBackups int, compress bool) *RotatingFile {
+	return &RotatingFile{
+		Path:         path,
+		MaxSizeBytes: maxSizeBytes,
+		MaxAge:       maxAge,
+		MaxBackups:   maxBackups,
+		Compress:     compress,
+	}
+}
+
+// Write implements io.Writer, rolling the file over first if needed.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	} else if r.needsRotation(len(p)) {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Close implements io.Closer.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+func (r *RotatingFile) needsRotation(nextWrite int) bool {
+	if r.MaxSizeBytes > 0 && r.size+int64(nextWrite) > r.MaxSizeBytes {
+		return true
+	}
+	if r.MaxAge > 0 && time.Since(r.openedAt) >= r.MaxAge {
+		return true
+	}
+	return false
+}
+
+func (r *RotatingFile) open() error {
+	if info, err := os.Stat(r.Path); err == nil {
+		r.size = info.Size()
+		r.openedAt = info.ModTime()
+	} else {
+		r.size = 0
+		r.openedAt = time.Now()
+	}
+
+	f, err := os.OpenFile(r.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	r.file = f
+	return nil
+}
+
+func (r *RotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	r.file = nil
+
+	backupPath := r.backupPath()
+	if err := os.Rename(r.Path, backupPath); err != nil {
+		return err
+	}
+
+	if r.Compress {
+		if err := gzipFile(backupPath); err != nil {
+			return err
+		}
+	}
+
+	if err := r.pruneBackups(); err != nil {
+		return err
+	}
+
+	return r.open()
+}
+
+// backupPath returns the timestamped name a rolled-over segment is renamed
+// to, eg. "access-20060102T150405.000000000.log" for Path "access.log".
+func (r *RotatingFile) backupPath() string {
+	dir := filepath.Dir(r.Path)
+	base := filepath.Base(r.Path)
+	ext := filepath.Ext(base)
+	name := strings.TrimSuffix(base, ext)
+
+	return filepath.Join(dir, fmt.Sprintf("%s-%s%s", name, time.Now().Format("20060102T150405.000000000"), ext))
+}
+
+// pruneBackups deletes the oldest rolled-over segments beyond MaxBackups.
+func (r *RotatingFile) pruneBackups() error {
+	if r.MaxBackups <= 0 {
+		return nil
+	}
+
+	dir := filepath.Dir(r.Path)
+	base := filepath.Base(r.Path)
+	ext := filepath.Ext(base)
+	name := strings.TrimSuffix(base, ext)
+
+	matches, err := filepath.Glob(filepath.Join(dir, name+"-*"))
+	if err != nil {
+		return err
+	}
+	if len(matches) <= r.MaxBackups {
+		return nil
+	}
+
+	// The timestamp format sorts lexically in chronological order.
+	sort.Strings(matches)
+
+	for _, old := range matches[:len(matches)-r.MaxBackups] {
+		if err := os.Remove(old); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gzipFile compresses path in place, replacing it with path+".gz".
+func gzipFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(path + ".gz")
+	if err != nil {
+		return err
+	}
+
+	gw := gzip.NewWriter(dst)
+	if _, err := io.Copy(gw, src); err != nil {
+		gw.Close()
+		dst.Close()
+		return err
+	}
+	if err := gw.Close(); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(path)
+}
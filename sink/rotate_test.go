@@ -0,0 +1,68 @@
+package sink
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRotatingFileRotatesOnSize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "access.log")
+
+	rf := NewRotatingFile(path, 10, 0, 0, false)
+	defer rf.Close()
+
+	if _, err := rf.Write([]byte("0123456789")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := rf.Write([]byte("more")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(dir, "access-*"))
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 rotated backup, got %d: %v", len(matches), matches)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read current log: %v", err)
+	}
+	if string(data) != "more" {
+		t.Errorf("expected current log to contain %q, got %q", "more", data)
+	}
+}
+
+func TestRotatingFileCompress(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "access.log")
+
+	rf := NewRotatingFile(path, 1, 0, 0, true)
+	defer rf.Close()
+
+	rf.Write([]byte("a"))
+	rf.Write([]byte("b"))
+
+	matches, _ := filepath.Glob(filepath.Join(dir, "access-*.gz"))
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 compressed backup, got %d: %v", len(matches), matches)
+	}
+}
+
+func TestRotatingFilePrunesBackups(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "access.log")
+
+	rf := NewRotatingFile(path, 1, 0, 2, false)
+	defer rf.Close()
+
+	for i := 0; i < 5; i++ {
+		rf.Write([]byte("x"))
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(dir, "access-*"))
+	if len(matches) > 2 {
+		t.Errorf("expected at most 2 retained backups, got %d: %v", len(matches), matches)
+	}
+}
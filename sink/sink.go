@@ -0,0 +1,57 @@
+// Package sink provides output destinations for logger.Logger: a rotating,
+// gzip-compressing file writer and a severity-filtered fan-out writer. Both
+// are plain io.Writers, so they also work anywhere else an io.Writer is
+// accepted.
+package sink
+
+import "io"
+
+// Level is the minimum severity a log line must have to reach a Sink.
+type Level int
+
+// Severity levels, lowest to highest.
+const (
+	LevelInfo Level = iota
+	LevelWarn
+	LevelError
+)
+
+// Sink pairs a destination Writer with the minimum Level a line must reach
+// to be written to it.
+type Sink struct {
+	Writer   io.Writer
+	MinLevel Level
+}
+
+// MultiSink fans a log line out to every configured Sink whose MinLevel is
+// at or below the line's severity, eg. pretty text to stdout plus JSON to a
+// rotated file plus errors-only to stderr.
+type MultiSink struct {
+	Sinks []Sink
+}
+
+// NewMultiSink returns a MultiSink fanning out to the given sinks.
+func NewMultiSink(sinks ...Sink) *MultiSink {
+	return &MultiSink{Sinks: sinks}
+}
+
+// Write implements io.Writer, treating every line as LevelInfo.
+func (m *MultiSink) Write(p []byte) (int, error) {
+	return m.WriteLevel(LevelInfo, p)
+}
+
+// WriteLevel writes p to every Sink whose MinLevel is at or below level.
+// It returns len(p) and the first error encountered, if any, matching the
+// io.Writer contract for callers that only care whether anything failed.
+func (m *MultiSink) WriteLevel(level Level, p []byte) (int, error) {
+	var firstErr error
+	for _, s := range m.Sinks {
+		if level < s.MinLevel {
+			continue
+		}
+		if _, err := s.Writer.Write(p); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return len(p), firstErr
+}
@@ -0,0 +1,40 @@
+package sink
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMultiSinkWriteLevel(t *testing.T) {
+	var info, warnAndUp, errOnly bytes.Buffer
+
+	m := NewMultiSink(
+		Sink{Writer: &info, MinLevel: LevelInfo},
+		Sink{Writer: &warnAndUp, MinLevel: LevelWarn},
+		Sink{Writer: &errOnly, MinLevel: LevelError},
+	)
+
+	m.WriteLevel(LevelInfo, []byte("info line\n"))
+	m.WriteLevel(LevelError, []byte("error line\n"))
+
+	if info.String() != "info line\nerror line\n" {
+		t.Errorf("unexpected info sink contents: %q", info.String())
+	}
+	if warnAndUp.String() != "error line\n" {
+		t.Errorf("unexpected warn sink contents: %q", warnAndUp.String())
+	}
+	if errOnly.String() != "error line\n" {
+		t.Errorf("unexpected error sink contents: %q", errOnly.String())
+	}
+}
+
+func TestMultiSinkWriteDefaultsToInfo(t *testing.T) {
+	var warnOnly bytes.Buffer
+
+	m := NewMultiSink(Sink{Writer: &warnOnly, MinLevel: LevelWarn})
+	m.Write([]byte("info line\n"))
+
+	if warnOnly.String() != "" {
+		t.Errorf("expected plain Write to be treated as info and filtered out, got %q", warnOnly.String())
+	}
+}
@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/unrolled/logger/sink"
+)
+
+func TestLoggerFansOutToSinks(t *testing.T) {
+	var out, errOnly bytes.Buffer
+
+	l := New(Options{
+		Out: &out,
+		Sinks: []sink.Sink{
+			{Writer: &errOnly, MinLevel: sink.LevelError},
+		},
+	})
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/foo", nil)
+	l.Handler(myHandler).ServeHTTP(res, req)
+
+	expectContainsTrue(t, out.String(), "200")
+	expect(t, errOnly.String(), "")
+}
+
+func TestLoggerFansOutErrorsToSinks(t *testing.T) {
+	var out, errOnly bytes.Buffer
+
+	l := New(Options{
+		Out: &out,
+		Sinks: []sink.Sink{
+			{Writer: &errOnly, MinLevel: sink.LevelError},
+		},
+	})
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/foo", nil)
+	l.Handler(myHandlerWithError).ServeHTTP(res, req)
+
+	expectContainsTrue(t, out.String(), "502")
+	expectContainsTrue(t, errOnly.String(), "502")
+}
@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// defaultTraceIDHeaders is used when Options.TraceIDHeaders is left unset.
+var defaultTraceIDHeaders = []string{"traceparent", "X-Request-ID", "X-Amzn-Trace-Id"}
+
+type requestIDKeyType struct{}
+
+// RequestIDKey is the context.Context key under which Handler stores the
+// resolved trace ID for the current request. Retrieve it with:
+//
+//	id, _ := r.Context().Value(logger.RequestIDKey).(string)
+var RequestIDKey = requestIDKeyType{}
+
+// parseTraceparent extracts the trace ID and span ID from a W3C `traceparent`
+// header value ("version-traceid-spanid-flags", eg.
+// "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"). ok is false
+// unless val has four dash-separated fields with a 32-character trace ID and
+// a 16-character span ID; the characters themselves are not validated.
+func parseTraceparent(val string) (traceID, spanID string, ok bool) {
+	parts := strings.Split(val, "-")
+	if len(parts) != 4 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+// generateHexID returns n random bytes hex-encoded, for use as a trace or span ID.
+func generateHexID(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// resolveTraceID extracts a trace ID from the first populated header in
+// traceIDHeaders (a traceparent that parseTraceparent rejects is skipped;
+// falling back to generator, or a random ID if generator is nil), and returns it alongside a freshly generated span ID for this hop.
+func resolveTraceID(r *http.Request, traceIDHeaders []string, generator func() string) (traceID, spanID string) {
+	for _, h := range traceIDHeaders {
+		val := r.Header.Get(h)
+		if val == "" {
+			continue
+		}
+		if strings.EqualFold(h, "traceparent") {
+			if tid, _, ok := parseTraceparent(val); ok {
+				traceID = tid
+				break
+			}
+			continue
+		}
+		traceID = val
+		break
+	}
+
+	if traceID == "" {
+		if generator != nil {
+			traceID = generator()
+		} else {
+			traceID = generateHexID(16)
+		}
+	}
+
+	return traceID, generateHexID(8)
+}
+
+// withRequestID returns a copy of ctx carrying traceID under RequestIDKey.
+func withRequestID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, RequestIDKey, traceID)
+}
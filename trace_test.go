@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	traceID, spanID, ok := parseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	expect(t, ok, true)
+	expect(t, traceID, "4bf92f3577b34da6a3ce929d0e0e4736")
+	expect(t, spanID, "00f067aa0ba902b7")
+}
+
+func TestParseTraceparentInvalid(t *testing.T) {
+	_, _, ok := parseTraceparent("not-a-traceparent")
+	expect(t, ok, false)
+}
+
+func TestLoggerPropagatesTraceparent(t *testing.T) {
+	buf := bytes.NewBufferString("")
+
+	var ctxTraceID string
+	capture := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctxTraceID, _ = r.Context().Value(RequestIDKey).(string)
+		w.Write([]byte("bar"))
+	})
+
+	l := New(Options{
+		Out:       buf,
+		Formatter: JSONFormatter{},
+	})
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/foo", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	l.Handler(capture).ServeHTTP(res, req)
+
+	expect(t, ctxTraceID, "4bf92f3577b34da6a3ce929d0e0e4736")
+	expect(t, res.Header().Get("X-Request-ID"), "4bf92f3577b34da6a3ce929d0e0e4736")
+	expectContainsTrue(t, buf.String(), `"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736"`)
+}
+
+func TestLoggerGeneratesTraceIDWhenMissing(t *testing.T) {
+	buf := bytes.NewBufferString("")
+
+	l := New(Options{
+		Out: buf,
+		RequestIDGenerator: func() string {
+			return "fixed-id"
+		},
+	})
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/foo", nil)
+	l.Handler(myHandler).ServeHTTP(res, req)
+
+	expect(t, res.Header().Get("X-Request-ID"), "fixed-id")
+	expectContainsTrue(t, buf.String(), "trace=fixed-id")
+}